@@ -0,0 +1,60 @@
+package base
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type recordingAuthorizer struct {
+	lastObj string
+	allow   bool
+}
+
+func (a *recordingAuthorizer) Enforce(sub, obj, act string) (bool, error) {
+	a.lastObj = obj
+	return a.allow, nil
+}
+
+func TestAuthorizeEnforcesAgainstMatchedRoutePattern(t *testing.T) {
+	rt := NewRouter("test")
+	authz := &recordingAuthorizer{allow: true}
+	rt.UseAuthorizer(authz)
+
+	rt.Get("/widgets/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/widgets/42", nil)
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, req)
+
+	const want = "/widgets/{id}"
+	if authz.lastObj != want {
+		t.Fatalf("Enforce obj = %q, want %q", authz.lastObj, want)
+	}
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for an allowed request, got %d", w.Code)
+	}
+}
+
+func TestAuthorizeDeniedDiscardsHandlerResponse(t *testing.T) {
+	rt := NewRouter("test")
+	authz := &recordingAuthorizer{allow: false}
+	rt.UseAuthorizer(authz)
+
+	rt.Get("/widgets/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("should not be visible"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/widgets/42", nil)
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a denied request, got %d", w.Code)
+	}
+	if w.Body.String() == "should not be visible" {
+		t.Fatal("expected the handler's buffered response to be discarded on deny")
+	}
+}
@@ -46,6 +46,17 @@ func (app *App) Log() Logger {
 	return app.log
 }
 
+// Revision returns the build revision set via SetRevision, or "" if unset.
+func (app *App) Revision() string {
+	return app.revision
+}
+
+// SetRevision records the build revision (e.g. a git SHA or version tag)
+// for this App, surfaced by EnableMetrics' build_info gauge.
+func (app *App) SetRevision(revision string) {
+	app.revision = revision
+}
+
 func genName(name, defName string) string {
 	if strings.Trim(name, " ") == "" {
 		return fmt.Sprintf("%s-%s", defName, nameSufix())
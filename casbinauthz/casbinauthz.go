@@ -0,0 +1,32 @@
+// Package casbinauthz provides a Casbin-backed implementation of
+// base.Authorizer, so the base package itself can stay free of a hard
+// Casbin dependency.
+package casbinauthz
+
+import (
+	"github.com/casbin/casbin/v2"
+)
+
+type (
+	// CasbinAuthorizer implements base.Authorizer on top of a Casbin
+	// enforcer, so RBAC or ABAC policies can gate router endpoints.
+	CasbinAuthorizer struct {
+		enforcer *casbin.Enforcer
+	}
+)
+
+// NewCasbinAuthorizer loads a Casbin model and policy from disk and returns
+// an Authorizer backed by them.
+func NewCasbinAuthorizer(modelPath, policyPath string) (*CasbinAuthorizer, error) {
+	enforcer, err := casbin.NewEnforcer(modelPath, policyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CasbinAuthorizer{enforcer: enforcer}, nil
+}
+
+// Enforce implements base.Authorizer.
+func (a *CasbinAuthorizer) Enforce(sub, obj, act string) (bool, error) {
+	return a.enforcer.Enforce(sub, obj, act)
+}
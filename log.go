@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"reflect"
@@ -107,6 +108,24 @@ func NewLogger(level, prefix, output string, flags ...int) *StdLogger {
 	}
 }
 
+// NewLoggerWithWriter is like NewLogger but writes to w instead of
+// os.Stdout, e.g. a RotatingWriter or a MultiWriter fanning out to several
+// destinations.
+func NewLoggerWithWriter(level, prefix, output string, w io.Writer, flags ...int) *StdLogger {
+	flag := 0
+	if len(flags) > 0 {
+		flag = flags[0]
+	}
+
+	return &StdLogger{
+		level:     level,
+		prefix:    prefix,
+		valuesStr: "",
+		output:    output,
+		logger:    log.New(w, "", flag),
+	}
+}
+
 func (sl *StdLogger) Enabled(level string) bool {
 	return sl.level == level
 }
@@ -0,0 +1,131 @@
+//go:build metrics
+// +build metrics
+
+package base
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const defaultMetricsPath = "/metrics"
+const defaultAdminAddr = ":9101"
+
+type (
+	// metricsConfig holds App.EnableMetrics options.
+	metricsConfig struct {
+		path      string
+		adminAddr string
+	}
+
+	// MetricsOption configures App.EnableMetrics.
+	MetricsOption func(*metricsConfig)
+)
+
+// WithMetricsPath overrides the default "/metrics" scrape path.
+func WithMetricsPath(path string) MetricsOption {
+	return func(c *metricsConfig) { c.path = path }
+}
+
+// WithAdminAddr overrides the default ":9101" address the metrics server
+// binds to, kept separate from JSONAPIRouter/WEBRouter so scrapes don't
+// interact with the rate limiter.
+func WithAdminAddr(addr string) MetricsOption {
+	return func(c *metricsConfig) { c.adminAddr = addr }
+}
+
+// prometheusRecorder implements base.MetricsRecorder.
+type prometheusRecorder struct {
+	inFlight  *prometheus.GaugeVec
+	requests  *prometheus.CounterVec
+	durations *prometheus.HistogramVec
+}
+
+func newPrometheusRecorder(namespace string, reg *prometheus.Registry) *prometheusRecorder {
+	pr := &prometheusRecorder{
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_in_flight_requests",
+			Help:      "Number of in-flight HTTP requests.",
+		}, []string{"method", "route"}),
+
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Total HTTP requests.",
+		}, []string{"method", "route", "status"}),
+
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency in seconds.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+	}
+
+	reg.MustRegister(pr.inFlight, pr.requests, pr.durations)
+
+	return pr
+}
+
+func (pr *prometheusRecorder) IncInFlight(method, pattern string) {
+	pr.inFlight.WithLabelValues(method, pattern).Inc()
+}
+
+func (pr *prometheusRecorder) DecInFlight(method, pattern string) {
+	pr.inFlight.WithLabelValues(method, pattern).Dec()
+}
+
+func (pr *prometheusRecorder) ObserveRequest(method, pattern, statusClass string, duration time.Duration) {
+	pr.requests.WithLabelValues(method, pattern, statusClass).Inc()
+	pr.durations.WithLabelValues(method, pattern, statusClass).Observe(duration.Seconds())
+}
+
+// EnableMetrics instruments JSONAPIRouter and WEBRouter with request
+// counters, in-flight gauges, and a latency histogram, and serves them via
+// promhttp on a separate admin http.Server so scrapes don't compete with
+// the rate limiter.
+func (app *App) EnableMetrics(namespace string, opts ...MetricsOption) error {
+	cfg := metricsConfig{path: defaultMetricsPath, adminAddr: defaultAdminAddr}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	reg.MustRegister(collectors.NewGoCollector())
+
+	buildInfo := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "build_info",
+		Help:      "Static metric with the running build's revision.",
+	}, []string{"revision"})
+	buildInfo.WithLabelValues(app.revision).Set(1)
+	reg.MustRegister(buildInfo)
+
+	recorder := newPrometheusRecorder(namespace, reg)
+
+	if app.JSONAPIRouter != nil {
+		app.JSONAPIRouter.SetMetricsRecorder(recorder)
+	}
+	if app.WEBRouter != nil {
+		app.WEBRouter.SetMetricsRecorder(recorder)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(cfg.path, promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+
+	server := &http.Server{Addr: cfg.adminAddr, Handler: mux}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			app.Log().Error(err, "metrics server stopped")
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package base
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeMetricsRecorder struct {
+	observedPatterns []string
+	decPatterns      []string
+}
+
+func (f *fakeMetricsRecorder) IncInFlight(method, pattern string) {}
+
+func (f *fakeMetricsRecorder) DecInFlight(method, pattern string) {
+	f.decPatterns = append(f.decPatterns, pattern)
+}
+
+func (f *fakeMetricsRecorder) ObserveRequest(method, pattern, statusClass string, duration time.Duration) {
+	f.observedPatterns = append(f.observedPatterns, pattern)
+}
+
+func TestMetricsMiddlewareUsesMatchedRoutePattern(t *testing.T) {
+	rt := NewRouter("test")
+	rec := &fakeMetricsRecorder{}
+	rt.SetMetricsRecorder(rec)
+
+	rt.Get("/widgets/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/widgets/42", nil)
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, req)
+
+	const want = "/widgets/{id}"
+
+	if len(rec.observedPatterns) != 1 || rec.observedPatterns[0] != want {
+		t.Fatalf("ObserveRequest pattern = %v, want [%q]", rec.observedPatterns, want)
+	}
+	if len(rec.decPatterns) != 1 || rec.decPatterns[0] != want {
+		t.Fatalf("DecInFlight pattern = %v, want [%q]", rec.decPatterns, want)
+	}
+}
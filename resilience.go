@@ -0,0 +1,319 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+type (
+	// Invoker is the sub-interface Services opt into so decorators like
+	// WithCircuitBreaker and WithRetry have something to wrap. Not part of
+	// Service/Worker itself, so existing implementations are unaffected.
+	Invoker interface {
+		Call(ctx context.Context, req interface{}) (interface{}, error)
+	}
+)
+
+type (
+	cbState int
+
+	// CBOpts configures WithCircuitBreaker.
+	CBOpts struct {
+		// Key scopes this breaker's state, e.g. Endpoint.Name().
+		Key string
+
+		// FailureThreshold is the failure rate (0..1) over Window that
+		// trips the breaker open. A value <= 0 disables tripping from the
+		// closed state entirely, so a zero-value CBOpts never opens.
+		FailureThreshold float64
+		Window           time.Duration
+
+		// MinRequests is the minimum number of requests observed in Window
+		// before the failure ratio is evaluated, so a single early failure
+		// (or success, against a misconfigured threshold) can't trip the
+		// breaker. Defaults to defaultMinRequests when <= 0.
+		MinRequests int
+
+		// CoolDown is how long the breaker stays open before allowing a
+		// half-open probe.
+		CoolDown time.Duration
+
+		// SuccessThreshold is the number of consecutive half-open
+		// successes required to close the breaker again.
+		SuccessThreshold int
+
+		// OnStateChange, if set, is called whenever the breaker transitions
+		// state, so callers can feed it into the metrics subsystem.
+		OnStateChange func(key, from, to string)
+	}
+
+	breakerService struct {
+		Service
+		inner Invoker
+		opts  CBOpts
+
+		mu                    sync.Mutex
+		state                 cbState
+		openedAt              time.Time
+		windowStart           time.Time
+		successes             int
+		failures              int
+		consecutiveSuccesses  int
+	}
+)
+
+const (
+	cbClosed cbState = iota
+	cbOpen
+	cbHalfOpen
+)
+
+// defaultMinRequests is used when CBOpts.MinRequests is unset, so the
+// failure-rate check has a reasonable sample size before it can trip.
+const defaultMinRequests = 10
+
+// ErrCircuitOpen is returned by a circuit-broken Invoker while the breaker
+// is open.
+var ErrCircuitOpen = errors.New("base: circuit breaker open")
+
+// WithCircuitBreaker wraps s with a three-state (closed/open/half-open)
+// circuit breaker: it opens once the failure rate over Window crosses
+// FailureThreshold, allows a probe after CoolDown, and closes again after
+// SuccessThreshold consecutive half-open successes. s must implement
+// Invoker or every Call returns an error.
+func WithCircuitBreaker(s Service, opts CBOpts) Service {
+	inner, _ := s.(Invoker)
+
+	return &breakerService{
+		Service:     s,
+		inner:       inner,
+		opts:        opts,
+		windowStart: time.Now(),
+	}
+}
+
+func (b *breakerService) Call(ctx context.Context, req interface{}) (interface{}, error) {
+	if b.inner == nil {
+		return nil, fmt.Errorf("base: circuit breaker: %q does not implement Invoker", b.Name())
+	}
+
+	if !b.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	resp, err := b.inner.Call(ctx, req)
+	b.record(err == nil)
+
+	return resp, err
+}
+
+func (b *breakerService) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+
+	switch b.state {
+	case cbOpen:
+		if now.Sub(b.openedAt) < b.opts.CoolDown {
+			return false
+		}
+		b.transition(cbHalfOpen, now)
+		return true
+	case cbHalfOpen:
+		return true
+	default:
+		b.resetWindowIfExpired(now)
+		return true
+	}
+}
+
+func (b *breakerService) record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+
+	if b.state == cbHalfOpen {
+		if success {
+			b.consecutiveSuccesses++
+			if b.consecutiveSuccesses >= b.opts.SuccessThreshold {
+				b.transition(cbClosed, now)
+			}
+			return
+		}
+
+		b.transition(cbOpen, now)
+		return
+	}
+
+	b.resetWindowIfExpired(now)
+
+	if success {
+		b.successes++
+	} else {
+		b.failures++
+	}
+
+	if b.opts.FailureThreshold <= 0 {
+		return
+	}
+
+	minRequests := b.opts.MinRequests
+	if minRequests <= 0 {
+		minRequests = defaultMinRequests
+	}
+
+	total := b.successes + b.failures
+	if total >= minRequests && float64(b.failures)/float64(total) >= b.opts.FailureThreshold {
+		b.transition(cbOpen, now)
+	}
+}
+
+func (b *breakerService) resetWindowIfExpired(now time.Time) {
+	if b.opts.Window <= 0 {
+		return
+	}
+	if now.Sub(b.windowStart) < b.opts.Window {
+		return
+	}
+
+	b.windowStart = now
+	b.successes = 0
+	b.failures = 0
+}
+
+func (b *breakerService) transition(to cbState, now time.Time) {
+	from := b.state
+	b.state = to
+
+	switch to {
+	case cbOpen:
+		b.openedAt = now
+	case cbHalfOpen:
+		b.consecutiveSuccesses = 0
+	case cbClosed:
+		b.windowStart = now
+		b.successes = 0
+		b.failures = 0
+	}
+
+	if from != to && b.opts.OnStateChange != nil {
+		b.opts.OnStateChange(b.opts.Key, cbStateName(from), cbStateName(to))
+	}
+}
+
+func cbStateName(s cbState) string {
+	switch s {
+	case cbOpen:
+		return "open"
+	case cbHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+type (
+	// RetryOpts configures WithRetry.
+	RetryOpts struct {
+		// Key identifies this retrier for OnAttempt, e.g. Endpoint.Name().
+		Key string
+
+		MaxAttempts int
+		MaxElapsed  time.Duration
+		BaseDelay   time.Duration
+		MaxDelay    time.Duration
+
+		// IsRetryable decides whether err should be retried. Nil retries
+		// every error except context cancellation/deadline.
+		IsRetryable func(err error) bool
+
+		// OnAttempt, if set, is called after every attempt so callers can
+		// feed it into the Logger or metrics subsystem.
+		OnAttempt func(key string, attempt int, err error)
+	}
+
+	retryService struct {
+		Service
+		inner Invoker
+		opts  RetryOpts
+	}
+)
+
+// WithRetry wraps s with exponential backoff and jitter, capped by
+// MaxAttempts and MaxElapsed. It never retries past context cancellation,
+// and skips retry for errors IsRetryable marks non-retryable. s must
+// implement Invoker or every Call returns an error.
+func WithRetry(s Service, opts RetryOpts) Service {
+	inner, _ := s.(Invoker)
+
+	return &retryService{
+		Service: s,
+		inner:   inner,
+		opts:    opts,
+	}
+}
+
+func (r *retryService) Call(ctx context.Context, req interface{}) (interface{}, error) {
+	if r.inner == nil {
+		return nil, fmt.Errorf("base: retry: %q does not implement Invoker", r.Name())
+	}
+
+	maxAttempts := r.opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	start := time.Now()
+	var resp interface{}
+	var err error
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		resp, err = r.inner.Call(ctx, req)
+
+		if r.opts.OnAttempt != nil {
+			r.opts.OnAttempt(r.opts.Key, attempt, err)
+		}
+
+		if err == nil || ctx.Err() != nil || !r.retryable(err) || attempt == maxAttempts {
+			return resp, err
+		}
+
+		if r.opts.MaxElapsed > 0 && time.Since(start) >= r.opts.MaxElapsed {
+			return resp, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return resp, ctx.Err()
+		case <-time.After(backoffDelay(r.opts.BaseDelay, r.opts.MaxDelay, attempt)):
+		}
+	}
+
+	return resp, err
+}
+
+func (r *retryService) retryable(err error) bool {
+	if r.opts.IsRetryable == nil {
+		return true
+	}
+	return r.opts.IsRetryable(err)
+}
+
+func backoffDelay(base, max time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		base = 100 * time.Millisecond
+	}
+
+	delay := base * time.Duration(uint64(1)<<uint(attempt-1))
+	if max > 0 && delay > max {
+		delay = max
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay)/2+1))
+}
@@ -0,0 +1,55 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubService struct {
+	*BaseService
+	callErr error
+}
+
+func (s *stubService) Call(_ context.Context, _ interface{}) (interface{}, error) {
+	return nil, s.callErr
+}
+
+func newStubService() *stubService {
+	return &stubService{BaseService: NewService("stub", nil)}
+}
+
+func TestCircuitBreakerZeroValueOptsNeverTrips(t *testing.T) {
+	s := newStubService()
+	cb := WithCircuitBreaker(s, CBOpts{}).(Invoker)
+
+	for i := 0; i < 3; i++ {
+		if _, err := cb.Call(context.Background(), nil); err != nil {
+			t.Fatalf("call %d: unexpected error with zero-value CBOpts: %v", i, err)
+		}
+	}
+}
+
+func TestCircuitBreakerRequiresMinRequestsBeforeTripping(t *testing.T) {
+	s := newStubService()
+	s.callErr = errors.New("boom")
+
+	cb := WithCircuitBreaker(s, CBOpts{
+		FailureThreshold: 0.5,
+		MinRequests:      5,
+		Window:           time.Minute,
+		CoolDown:         time.Minute,
+		SuccessThreshold: 1,
+	}).(Invoker)
+
+	for i := 0; i < 5; i++ {
+		if _, err := cb.Call(context.Background(), nil); err == ErrCircuitOpen {
+			t.Fatalf("call %d: breaker opened before MinRequests was reached", i)
+		}
+	}
+
+	if _, err := cb.Call(context.Background(), nil); err != ErrCircuitOpen {
+		t.Fatalf("expected breaker open once the failure ratio crossed FailureThreshold, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package base
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/go-chi/chi"
+)
+
+type (
+	// RouteInfo describes a single mounted route, as discovered by
+	// Router.PrintTree.
+	RouteInfo struct {
+		Method      string
+		Pattern     string
+		HandlerName string
+		Middlewares []string
+	}
+)
+
+// String renders a RouteInfo as aligned columns, suitable for printing from
+// an init hook at boot.
+func (ri RouteInfo) String() string {
+	return fmt.Sprintf("%-7s %-40s %s", ri.Method, ri.Pattern, ri.HandlerName)
+}
+
+// PrintTree walks the router's mounted chi trees and returns the resolved
+// routes, handlers, and middleware stack for each one. It's primarily meant
+// for operators verifying what's actually mounted on JSONAPIRouter and
+// WEBRouter.
+func (r *Router) PrintTree() []RouteInfo {
+	var infos []RouteInfo
+
+	_ = chi.Walk(r.Router, func(method, pattern string, handler http.Handler, mws ...func(http.Handler) http.Handler) error {
+		names := make([]string, 0, len(mws))
+		for _, mw := range mws {
+			names = append(names, funcName(mw))
+		}
+
+		infos = append(infos, RouteInfo{
+			Method:      method,
+			Pattern:     pattern,
+			HandlerName: funcName(handler),
+			Middlewares: names,
+		})
+
+		return nil
+	})
+
+	return infos
+}
+
+// MountRoutesAdmin registers a read-only route-introspection handler at
+// ResAdmin("routes", adminPathPfx), rendering RouteInfo as JSON or an HTML
+// table depending on the request's Accept header.
+func (r *Router) MountRoutesAdmin(adminPathPfx string) {
+	r.Get(ResAdmin("routes", adminPathPfx), func(w http.ResponseWriter, req *http.Request) {
+		routes := r.PrintTree()
+
+		if strings.Contains(req.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(routes)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(renderRoutesTable(routes))
+	})
+}
+
+func renderRoutesTable(routes []RouteInfo) []byte {
+	buf := bytes.NewBufferString("<table><thead><tr><th>Method</th><th>Pattern</th><th>Handler</th><th>Middlewares</th></tr></thead><tbody>")
+
+	for _, ri := range routes {
+		buf.WriteString("<tr><td>")
+		buf.WriteString(html.EscapeString(ri.Method))
+		buf.WriteString("</td><td>")
+		buf.WriteString(html.EscapeString(ri.Pattern))
+		buf.WriteString("</td><td>")
+		buf.WriteString(html.EscapeString(ri.HandlerName))
+		buf.WriteString("</td><td>")
+		buf.WriteString(html.EscapeString(strings.Join(ri.Middlewares, ", ")))
+		buf.WriteString("</td></tr>")
+	}
+
+	buf.WriteString("</tbody></table>")
+
+	return buf.Bytes()
+}
+
+// funcName resolves the name of a function/handler value for display in
+// RouteInfo. reflect.Value.Pointer only supports Chan, Func, Map, Ptr,
+// Slice, and UnsafePointer kinds, so any other kind (e.g. a plain struct
+// implementing http.Handler, a valid way to Handle/Mount on chi.Router)
+// falls back to its type name instead of panicking.
+func funcName(v interface{}) string {
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Map, reflect.Ptr, reflect.Slice, reflect.UnsafePointer:
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+
+	fn := runtime.FuncForPC(rv.Pointer())
+	if fn == nil {
+		return "unknown"
+	}
+
+	return fn.Name()
+}
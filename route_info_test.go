@@ -0,0 +1,17 @@
+package base
+
+import (
+	"net/http"
+	"testing"
+)
+
+type structHandler struct{}
+
+func (structHandler) ServeHTTP(http.ResponseWriter, *http.Request) {}
+
+func TestFuncNameDoesNotPanicOnStructHandler(t *testing.T) {
+	name := funcName(structHandler{})
+	if name == "" {
+		t.Fatal("expected a non-empty fallback name for a struct-valued handler")
+	}
+}
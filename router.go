@@ -1,7 +1,12 @@
 package base
 
 import (
+	"bytes"
+	"hash/fnv"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -16,32 +21,99 @@ type (
 		name string
 		chi.Router
 
-		// Hourly rate
-		maxReqPerHour uint64
-		hourlyRate    int
-		hourlyLimiter *baseLimiter
+		// Per-IP hourly quota
+		hourlyLimiters *limiterStore
 
-		// Daily
-		maxReqPerDay uint64
-		dailyRate    int
-		dailyLimiter *baseLimiter
+		// Per-IP daily quota
+		dailyLimiters *limiterStore
+
+		// keyFunc derives the throttling key (IP by default) from a request.
+		keyFunc func(*http.Request) string
+
+		// whitelist holds CIDRs that bypass throttling entirely.
+		whitelist []*net.IPNet
+
+		// Access logging
+		accessLogger  Logger
+		accessSkipper func(*http.Request) bool
+
+		// Authorization
+		authorizer   Authorizer
+		actionMapper func(method string) string
+
+		// Metrics
+		metricsRecorder MetricsRecorder
 	}
 )
 
 type (
-	baseLimiter struct {
+	// MetricsRecorder receives request instrumentation from
+	// Router.MetricsMiddleware. Prometheus is the reference implementation,
+	// wired in via App.EnableMetrics (see the "metrics" build tag).
+	MetricsRecorder interface {
+		IncInFlight(method, pattern string)
+		DecInFlight(method, pattern string)
+		ObserveRequest(method, pattern, statusClass string, duration time.Duration)
+	}
+)
+
+type (
+	// Authorizer decides whether a (subject, object, action) tuple is
+	// allowed. Casbin is the reference implementation; see NewCasbinAuthorizer
+	// in the casbinauthz subpackage.
+	Authorizer interface {
+		Enforce(sub, obj, act string) (bool, error)
+	}
+)
+
+type (
+	// statusWriter wraps http.ResponseWriter to capture the status code and
+	// bytes written for AccessLog, without changing response behavior.
+	statusWriter struct {
+		http.ResponseWriter
+		status       int
+		bytesWritten int
+	}
+)
+
+type (
+	// limiterEntry is a single client's limiter plus the last time it was used,
+	// so idle entries can be evicted from the store.
+	limiterEntry struct {
+		limiter *rate.Limiter
+		last    time.Time
+	}
+
+	// limiterShard is one stripe of a limiterStore, guarded by its own mutex so
+	// unrelated clients never contend on the same lock.
+	limiterShard struct {
 		sync.Mutex
-		*rate.Limiter
-		last time.Time
+		entries map[string]*limiterEntry
+	}
+
+	// limiterStore is a sharded collection of per-key rate limiters. Idle
+	// entries are swept out past ttl, so its size tracks the distinct keys
+	// seen within one ttl; there is no cap on the number of entries.
+	limiterStore struct {
+		shards [numLimiterShards]*limiterShard
+		limit  rate.Limit
+		burst  int
+		ttl    time.Duration
 	}
 )
 
 const (
+	numLimiterShards = 32
+	limiterTTL       = 10 * time.Minute
+
 	hourInSecs = 3600
 	dayInSecs  = hourInSecs * 24
-	zeroInt    = 0
-	zeroInt64  = uint64(zeroInt)
-	maxInt64   = uint64(1<<64 - 1)
+)
+
+// RateLimit header names written on throttled responses.
+const (
+	rateLimitRemainingHeader = "X-RateLimit-Remaining"
+	retryAfterHeader         = "Retry-After"
 )
 
 func NewRouter(name string) *Router {
@@ -51,27 +123,15 @@ func NewRouter(name string) *Router {
 		name:   name,
 		Router: chi.NewRouter(),
 
-		// Hourly
-		maxReqPerHour: maxInt64,
-		hourlyRate:    0,
-		hourlyLimiter: &baseLimiter{
-			Mutex:   sync.Mutex{},
-			Limiter: rate.NewLimiter(zeroInt, 1), // i.e.: 120 = 30 reqs / hour
-			last:    time.Now(),
-		},
-
-		// Daily
-		maxReqPerDay: maxInt64,
-		dailyRate:    0,
-		dailyLimiter: &baseLimiter{
-			Mutex:   sync.Mutex{},
-			Limiter: rate.NewLimiter(zeroInt, 1), // i.e.: 1728 = 50 reqs / day
-			last:    time.Now(),
-		},
+		hourlyLimiters: newLimiterStore(rate.Inf, 0, limiterTTL),
+		dailyLimiters:  newLimiterStore(rate.Inf, 0, limiterTTL),
+
+		keyFunc: defaultLimiterKey,
 	}
 
 	rt.Use(middleware.RequestID)
 	rt.Use(middleware.RealIP)
+	rt.Use(rt.AccessLog)
 	rt.Use(middleware.Recoverer)
 	rt.Use(middleware.Timeout(60 * time.Second))
 	rt.Use(rt.MethodOverride)
@@ -85,20 +145,101 @@ func (r *Router) Name() string {
 	return r.name
 }
 
-func (r *Router) SetHourlyRate(maxReqsPerHour int) {
+// SetPerIPHourlyRate sets the per-client hourly request quota. A value <= 0
+// disables the hourly limiter, same as an unconfigured Router.
+func (r *Router) SetPerIPHourlyRate(maxReqsPerHour int) {
 	if maxReqsPerHour <= 0 {
-		r.hourlyRate = 0
+		r.hourlyLimiters = newLimiterStore(rate.Inf, 0, limiterTTL)
+		return
 	}
-
-	r.hourlyRate = hourInSecs / maxReqsPerHour
+	r.hourlyLimiters = newLimiterStore(perHourLimit(maxReqsPerHour), 1, limiterTTL)
 }
 
-func (r *Router) SetDailyRate(maxReqsPerDay int) {
+// SetPerIPDailyRate sets the per-client daily request quota. A value <= 0
+// disables the daily limiter, same as an unconfigured Router.
+func (r *Router) SetPerIPDailyRate(maxReqsPerDay int) {
 	if maxReqsPerDay <= 0 {
-		r.dailyRate = 0
+		r.dailyLimiters = newLimiterStore(rate.Inf, 0, limiterTTL)
+		return
+	}
+	r.dailyLimiters = newLimiterStore(perDayLimit(maxReqsPerDay), 1, limiterTTL)
+}
+
+// SetLimiterKeyFunc overrides how a request is mapped to a throttling key.
+// The default keys by client IP (as resolved by middleware.RealIP); callers
+// may swap in session ID, API key, or any other derived identity.
+func (r *Router) SetLimiterKeyFunc(fn func(*http.Request) string) {
+	if fn == nil {
+		return
+	}
+	r.keyFunc = fn
+}
+
+// SetIPWhitelist configures CIDRs that bypass throttling entirely, e.g.
+// internal health checks or trusted upstream proxies.
+func (r *Router) SetIPWhitelist(cidrs ...string) error {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return err
+		}
+		nets = append(nets, ipNet)
+	}
+
+	r.whitelist = nets
+
+	return nil
+}
+
+// SetAccessLogger sets the Logger that AccessLog emits request lines through.
+// When unset, AccessLog is a no-op.
+func (r *Router) SetAccessLogger(log Logger) {
+	r.accessLogger = log
+}
+
+// SetAccessLogSkipper sets a predicate used to suppress access log lines,
+// e.g. for health-check endpoints.
+func (r *Router) SetAccessLogSkipper(fn func(*http.Request) bool) {
+	r.accessSkipper = fn
+}
+
+// UseAuthorizer wires a (subject, object, action) Authorizer into the
+// router and registers the Authorize middleware. Call it before mounting
+// routes, same as any other chi middleware.
+func (r *Router) UseAuthorizer(a Authorizer) {
+	r.authorizer = a
+	r.Use(r.Authorize)
+}
+
+// SetActionMapper overrides how an HTTP method is mapped to an authorization
+// action (default: GET -> read, everything else -> write, DELETE -> delete).
+func (r *Router) SetActionMapper(fn func(method string) string) {
+	if fn == nil {
+		return
 	}
+	r.actionMapper = fn
+}
 
-	r.dailyRate = dayInSecs / maxReqsPerDay
+// SetMetricsRecorder wires a MetricsRecorder into the router and registers
+// MetricsMiddleware. Call it before mounting routes, same as any other chi
+// middleware.
+func (r *Router) SetMetricsRecorder(m MetricsRecorder) {
+	r.metricsRecorder = m
+	r.Use(r.MetricsMiddleware)
+}
+
+// perHourLimit assumes maxReqsPerHour > 0; the <= 0 case is special-cased by
+// SetPerIPHourlyRate before calling this.
+func perHourLimit(maxReqsPerHour int) rate.Limit {
+	return rate.Limit(float64(maxReqsPerHour) / hourInSecs)
+}
+
+// perDayLimit assumes maxReqsPerDay > 0; the <= 0 case is special-cased by
+// SetPerIPDailyRate before calling this.
+func perDayLimit(maxReqsPerDay int) rate.Limit {
+	return rate.Limit(float64(maxReqsPerDay) / dayInSecs)
 }
 
 // Middlewares
@@ -126,24 +267,321 @@ func (rt *Router) CSRFProtection(next http.Handler) http.Handler {
 	return csrf.Protect([]byte("32-byte-long-auth-key"), csrf.Secure(false))(next)
 }
 
-// ThrottleLimit add rate limit protection
+// ThrottleLimit add rate limit protection, applied per client (by default the
+// real IP) via sharded token buckets rather than one shared global limiter.
 func (rt *Router) ThrottleLimit(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		updateLimiter(rt.hourlyLimiter)
-		updateLimiter(rt.dailyLimiter)
+		// The whitelist bypass is always IP-based, regardless of what
+		// SetLimiterKeyFunc is configured to key throttling by (session ID,
+		// API key, ...) — otherwise a custom key func silently disables the
+		// CIDR whitelist for everyone.
+		if rt.isWhitelisted(defaultLimiterKey(r)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := rt.keyFunc(r)
+
+		now := time.Now()
+
+		hourlyRes := rt.hourlyLimiters.reserve(key, now)
+		dailyRes := rt.dailyLimiters.reserve(key, now)
+
+		delay := hourlyRes.DelayFrom(now)
+		if d := dailyRes.DelayFrom(now); d > delay {
+			delay = d
+		}
+
+		if delay > 0 {
+			hourlyRes.CancelAt(now)
+			dailyRes.CancelAt(now)
 
-		if !(rt.hourlyLimiter.Allow() && rt.dailyLimiter.Allow()) {
+			w.Header().Set(rateLimitRemainingHeader, "0")
+			w.Header().Set(retryAfterHeader, strconv.Itoa(int(delay.Seconds()+1)))
 			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
 			return
 		}
 
+		remaining := rt.hourlyLimiters.tokensRemaining(key, now)
+		if d := rt.dailyLimiters.tokensRemaining(key, now); d < remaining {
+			remaining = d
+		}
+		if !math.IsInf(remaining, 1) {
+			w.Header().Set(rateLimitRemainingHeader, strconv.Itoa(int(remaining)))
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
 
-func updateLimiter(l *baseLimiter) {
-	l.Lock()
-	defer l.Unlock()
+// AccessLog emits a single structured log line per request through the
+// Logger set via SetAccessLogger, using Info for 2xx/3xx responses and Error
+// for 5xx responses.
+func (rt *Router) AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rt.accessLogger == nil || (rt.accessSkipper != nil && rt.accessSkipper(r)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(sw, r)
+
+		kv := []interface{}{
+			"request_id", middleware.GetReqID(r.Context()),
+			"ip", r.RemoteAddr,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"route", chi.RouteContext(r.Context()).RoutePattern(),
+			"status", sw.status,
+			"bytes", sw.bytesWritten,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"user_agent", r.UserAgent(),
+			"referer", r.Referer(),
+		}
+
+		if sid, ok := r.Context().Value(SessionCtxKey).(string); ok && sid != "" {
+			kv = append(kv, "session_id", sid)
+		}
+
+		if sw.status >= http.StatusInternalServerError {
+			rt.accessLogger.Error(nil, "request completed", kv...)
+		} else {
+			rt.accessLogger.Info("request completed", kv...)
+		}
+	})
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	sw.status = status
+	sw.ResponseWriter.WriteHeader(status)
+}
 
-	l.last = time.Now()
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	n, err := sw.ResponseWriter.Write(b)
+	sw.bytesWritten += n
+	return n, err
+}
+
+// Authorize enforces the router's Authorizer, if any, against the subject
+// stored under SessionCtxKey, the matched route pattern as object, and the
+// HTTP method mapped to an action. Denied or failed checks render a 403
+// through the same error path used by ThrottleLimit.
+//
+// chi only finalizes RoutePattern() once its own mux has matched the route,
+// which happens inside next.ServeHTTP — by the time Authorize could read it
+// beforehand it is always "". So the handler runs against a deferredWriter
+// first; once next.ServeHTTP returns, the pattern is known, Enforce runs,
+// and the buffered response is only flushed to the real ResponseWriter on
+// an allow. A deny discards whatever the handler wrote and sends 403
+// instead. This means a denied handler's side effects (e.g. writes it made
+// before returning) still happened — chi gives no earlier hook into the
+// matched pattern for a router-wide middleware.
+func (rt *Router) Authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rt.authorizer == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		dw := newDeferredWriter()
+		next.ServeHTTP(dw, r)
+
+		sub, _ := r.Context().Value(SessionCtxKey).(string)
+		obj := chi.RouteContext(r.Context()).RoutePattern()
+		act := rt.mapAction(r.Method)
+
+		allowed, err := rt.authorizer.Enforce(sub, obj, act)
+		if err != nil || !allowed {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		dw.flushTo(w)
+	})
+}
+
+type (
+	// deferredWriter buffers a response until Authorize knows whether to
+	// flush it (allowed) or discard it in favor of a 403 (denied).
+	deferredWriter struct {
+		header http.Header
+		body   bytes.Buffer
+		status int
+	}
+)
+
+func newDeferredWriter() *deferredWriter {
+	return &deferredWriter{header: make(http.Header), status: http.StatusOK}
+}
+
+func (dw *deferredWriter) Header() http.Header {
+	return dw.header
+}
+
+func (dw *deferredWriter) WriteHeader(status int) {
+	dw.status = status
+}
+
+func (dw *deferredWriter) Write(b []byte) (int, error) {
+	return dw.body.Write(b)
+}
+
+func (dw *deferredWriter) flushTo(w http.ResponseWriter) {
+	for k, values := range dw.header {
+		for _, v := range values {
+			w.Header().Add(k, v)
+		}
+	}
+
+	w.WriteHeader(dw.status)
+	_, _ = w.Write(dw.body.Bytes())
+}
+
+// MetricsMiddleware reports in-flight and completed request metrics through
+// the router's MetricsRecorder, labeled by method, matched route pattern,
+// and status class.
+func (rt *Router) MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rt.metricsRecorder == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// RoutePattern() isn't populated until chi matches the route, which
+		// happens inside next.ServeHTTP — read it before that call and
+		// every request reports an empty pattern. IncInFlight necessarily
+		// fires before the match; DecInFlight and ObserveRequest read the
+		// pattern again afterward via this closure so they get the real one.
+		pattern := chi.RouteContext(r.Context()).RoutePattern()
+
+		rt.metricsRecorder.IncInFlight(r.Method, pattern)
+
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+
+		defer func() {
+			pattern = chi.RouteContext(r.Context()).RoutePattern()
+			rt.metricsRecorder.DecInFlight(r.Method, pattern)
+			rt.metricsRecorder.ObserveRequest(r.Method, pattern, statusClass(sw.status), time.Since(start))
+		}()
+
+		next.ServeHTTP(sw, r)
+	})
+}
+
+func statusClass(status int) string {
+	return strconv.Itoa(status/100) + "xx"
+}
+
+func (rt *Router) mapAction(method string) string {
+	if rt.actionMapper != nil {
+		return rt.actionMapper(method)
+	}
+
+	switch method {
+	case GetMethod:
+		return "read"
+	case DeleteMethod:
+		return "delete"
+	default:
+		return "write"
+	}
+}
+
+func (rt *Router) isWhitelisted(key string) bool {
+	if len(rt.whitelist) == 0 {
+		return false
+	}
+
+	ip := net.ParseIP(key)
+	if ip == nil {
+		return false
+	}
+
+	for _, ipNet := range rt.whitelist {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// defaultLimiterKey keys by the client's real IP, stripping the port when present.
+func defaultLimiterKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func newLimiterStore(limit rate.Limit, burst int, ttl time.Duration) *limiterStore {
+	s := &limiterStore{
+		limit: limit,
+		burst: burst,
+		ttl:   ttl,
+	}
+
+	for i := range s.shards {
+		s.shards[i] = &limiterShard{entries: make(map[string]*limiterEntry)}
+	}
+
+	return s
+}
+
+func (s *limiterStore) reserve(key string, now time.Time) *rate.Reservation {
+	shard := s.shardFor(key)
+
+	shard.Lock()
+	defer shard.Unlock()
+
+	shard.evictIdle(now, s.ttl)
+
+	entry, ok := shard.entries[key]
+	if !ok {
+		entry = &limiterEntry{limiter: rate.NewLimiter(s.limit, s.burst)}
+		shard.entries[key] = entry
+	}
+	entry.last = now
+
+	return entry.limiter.ReserveN(now, 1)
+}
+
+// tokensRemaining reports the tokens left for key, or +Inf for an
+// unconfigured (rate.Inf) store — TokensAt has no Inf special-case of its
+// own and would otherwise report 0 for a store that never actually throttles.
+func (s *limiterStore) tokensRemaining(key string, now time.Time) float64 {
+	if s.limit == rate.Inf {
+		return math.Inf(1)
+	}
+
+	shard := s.shardFor(key)
+
+	shard.Lock()
+	defer shard.Unlock()
+
+	entry, ok := shard.entries[key]
+	if !ok {
+		return float64(s.burst)
+	}
+
+	return entry.limiter.TokensAt(now)
+}
+
+func (s *limiterStore) shardFor(key string) *limiterShard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return s.shards[h.Sum32()%numLimiterShards]
+}
+
+func (shard *limiterShard) evictIdle(now time.Time, ttl time.Duration) {
+	for key, entry := range shard.entries {
+		if now.Sub(entry.last) > ttl {
+			delete(shard.entries, key)
+		}
+	}
 }
@@ -0,0 +1,73 @@
+package base
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestThrottleLimitUnconfiguredRouterOmitsRemainingHeader(t *testing.T) {
+	rt := NewRouter("test")
+	rt.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.1:12345"
+	rec := httptest.NewRecorder()
+
+	rt.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	if v := rec.Header().Get(rateLimitRemainingHeader); v != "" {
+		t.Fatalf("expected no %s header on an unconfigured router, got %q", rateLimitRemainingHeader, v)
+	}
+}
+
+func TestSetPerIPHourlyRateZeroDisablesLimiter(t *testing.T) {
+	rt := NewRouter("test")
+	rt.SetPerIPHourlyRate(0)
+	rt.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.2:12345"
+
+	for i := 0; i < 5; i++ {
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: expected status 200 with hourly rate disabled, got %d", i, rec.Code)
+		}
+	}
+}
+
+func TestIPWhitelistBypassesCustomLimiterKeyFunc(t *testing.T) {
+	rt := NewRouter("test")
+	if err := rt.SetIPWhitelist("203.0.113.5/32"); err != nil {
+		t.Fatalf("SetIPWhitelist: %v", err)
+	}
+	// A session-based key func would otherwise make isWhitelisted call
+	// net.ParseIP on a non-IP string and silently stop bypassing anyone.
+	rt.SetLimiterKeyFunc(func(*http.Request) string { return "session-123" })
+	rt.SetPerIPHourlyRate(1)
+
+	rt.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.5:12345"
+
+	for i := 0; i < 5; i++ {
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: expected whitelisted IP to bypass throttling, got %d", i, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package base
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type (
+	// RotatingWriter is an io.WriteCloser that rotates the underlying file
+	// by size, age, and backup count, optionally gzip-compressing rotated
+	// segments. It is safe for concurrent use and reopens its file on
+	// SIGHUP for logrotate-style external rotation.
+	RotatingWriter struct {
+		mu   sync.Mutex
+		path string
+
+		MaxSizeBytes int64
+		MaxAge       time.Duration
+		MaxBackups   int
+		Compress     bool
+
+		file      *os.File
+		size      int64
+		openedAt  time.Time
+		sighup    chan os.Signal
+		closeOnce sync.Once
+	}
+)
+
+// NewRotatingWriter opens (or creates) path and returns a RotatingWriter
+// that rotates it according to MaxSizeBytes, MaxAge, and MaxBackups.
+// A zero value for any of those disables that rotation trigger.
+func NewRotatingWriter(path string, maxSizeBytes int64, maxAge time.Duration, maxBackups int, compress bool) (*RotatingWriter, error) {
+	rw := &RotatingWriter{
+		path:         path,
+		MaxSizeBytes: maxSizeBytes,
+		MaxAge:       maxAge,
+		MaxBackups:   maxBackups,
+		Compress:     compress,
+	}
+
+	if err := rw.openCurrent(); err != nil {
+		return nil, err
+	}
+
+	rw.sighup = make(chan os.Signal, 1)
+	signal.Notify(rw.sighup, syscall.SIGHUP)
+	go rw.watchReopen()
+
+	return rw, nil
+}
+
+func (rw *RotatingWriter) Write(p []byte) (int, error) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if rw.shouldRotate(len(p)) {
+		if err := rw.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rw.file.Write(p)
+	rw.size += int64(n)
+
+	return n, err
+}
+
+// Close stops the SIGHUP watcher and closes the underlying file.
+func (rw *RotatingWriter) Close() error {
+	var err error
+
+	rw.closeOnce.Do(func() {
+		signal.Stop(rw.sighup)
+		close(rw.sighup)
+
+		rw.mu.Lock()
+		defer rw.mu.Unlock()
+		err = rw.file.Close()
+	})
+
+	return err
+}
+
+func (rw *RotatingWriter) shouldRotate(nextWrite int) bool {
+	if rw.MaxSizeBytes > 0 && rw.size+int64(nextWrite) > rw.MaxSizeBytes {
+		return true
+	}
+
+	if rw.MaxAge > 0 && time.Since(rw.openedAt) > rw.MaxAge {
+		return true
+	}
+
+	return false
+}
+
+func (rw *RotatingWriter) openCurrent() error {
+	f, err := os.OpenFile(rw.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	rw.file = f
+	rw.size = info.Size()
+	rw.openedAt = info.ModTime()
+
+	return nil
+}
+
+// rotate closes the current file, renames it aside with a timestamp,
+// reopens path fresh, and prunes backups beyond MaxBackups / MaxAge.
+func (rw *RotatingWriter) rotate() error {
+	if err := rw.file.Close(); err != nil {
+		return err
+	}
+
+	backup := fmt.Sprintf("%s.%s", rw.path, time.Now().Format("20060102T150405.000000000"))
+	if err := os.Rename(rw.path, backup); err != nil {
+		return err
+	}
+
+	if rw.Compress {
+		if err := gzipFile(backup); err != nil {
+			return err
+		}
+		backup += ".gz"
+	}
+
+	if err := rw.openCurrent(); err != nil {
+		return err
+	}
+
+	return rw.pruneBackups()
+}
+
+func (rw *RotatingWriter) pruneBackups() error {
+	pattern := rw.path + ".*"
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(matches)
+
+	if rw.MaxAge > 0 {
+		cutoff := time.Now().Add(-rw.MaxAge)
+		kept := matches[:0]
+
+		for _, m := range matches {
+			info, err := os.Stat(m)
+			if err != nil {
+				continue
+			}
+			if info.ModTime().Before(cutoff) {
+				os.Remove(m)
+				continue
+			}
+			kept = append(kept, m)
+		}
+
+		matches = kept
+	}
+
+	if rw.MaxBackups > 0 && len(matches) > rw.MaxBackups {
+		for _, m := range matches[:len(matches)-rw.MaxBackups] {
+			os.Remove(m)
+		}
+	}
+
+	return nil
+}
+
+func (rw *RotatingWriter) watchReopen() {
+	for range rw.sighup {
+		rw.mu.Lock()
+		rw.file.Close()
+		rw.openCurrent()
+		rw.mu.Unlock()
+	}
+}
+
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	gw := gzip.NewWriter(dst)
+	if _, err := io.Copy(gw, src); err != nil {
+		gw.Close()
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// MultiWriter tees writes to several destinations, e.g. stdout and a
+// RotatingWriter, failing the write if any destination fails.
+func MultiWriter(writers ...io.Writer) io.Writer {
+	return io.MultiWriter(writers...)
+}
@@ -0,0 +1,136 @@
+// Package zaplog provides a Zap-backed implementation of base.Logger for
+// callers who want high-performance structured logging without changing
+// any service or worker code.
+package zaplog
+
+import (
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/nasapic/base"
+)
+
+type (
+	// ZapLogger is a base.Logger implementation backed by a
+	// zap.SugaredLogger. It is a drop-in replacement for base.StdLogger.
+	ZapLogger struct {
+		level string
+		atom  zap.AtomicLevel
+		sugar *zap.SugaredLogger
+	}
+)
+
+// noneLevel disables logging entirely; it sits above zapcore's highest
+// defined level so nothing is ever enabled.
+const noneLevel = zapcore.FatalLevel + 1
+
+// NewZapLogger returns a Logger backed by zap, mirroring NewLogger's
+// signature so it can be swapped into NewApp, NewWorker, NewEndpoint, and
+// NewService as a drop-in dependency.
+// Example: zaplog.NewZapLogger(base.LogLevel.Info, "app", base.LogOutput.JSON)
+func NewZapLogger(level, prefix, output string) *ZapLogger {
+	atom := zap.NewAtomicLevel()
+	atom.SetLevel(toZapLevel(level))
+
+	encoder := zapcore.NewConsoleEncoder(encoderConfig())
+	if output == base.LogOutput.JSON {
+		encoder = zapcore.NewJSONEncoder(encoderConfig())
+	}
+
+	core := zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), atom)
+
+	logger := zap.New(core)
+	if prefix != "" {
+		logger = logger.Named(prefix)
+	}
+
+	return &ZapLogger{
+		level: level,
+		atom:  atom,
+		sugar: logger.Sugar(),
+	}
+}
+
+func encoderConfig() zapcore.EncoderConfig {
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.TimeKey = "ts"
+	cfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	return cfg
+}
+
+func (zl *ZapLogger) Enabled(level string) bool {
+	return zl.level == level
+}
+
+func (zl *ZapLogger) SetLevel(level string) {
+	zl.level = level
+	zl.atom.SetLevel(toZapLevel(level))
+}
+
+func (zl *ZapLogger) Debug(msg string, keysAndValues ...interface{}) {
+	zl.sugar.Debugw(msg, convertKV(keysAndValues)...)
+}
+
+func (zl *ZapLogger) Info(msg string, keysAndValues ...interface{}) {
+	zl.sugar.Infow(msg, convertKV(keysAndValues)...)
+}
+
+func (zl *ZapLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	kv := append([]interface{}{"error", errString(err)}, convertKV(keysAndValues)...)
+	zl.sugar.Errorw(msg, kv...)
+}
+
+func errString(err error) interface{} {
+	if err == nil {
+		return nil
+	}
+	return err.Error()
+}
+
+// convertKV rewrites the key/value pairs base.Logger callers pass so that
+// zapcore.ObjectMarshaler implementations are logged via zap.Object (for
+// efficient structured encoding) and base.Marshalable implementations fall
+// back to their Log() representation, same as base.StdLogger.
+func convertKV(kvList []interface{}) []interface{} {
+	out := make([]interface{}, 0, len(kvList))
+
+	for i := 0; i < len(kvList); i += 2 {
+		if i+1 >= len(kvList) {
+			out = append(out, kvList[i])
+			continue
+		}
+
+		k, _ := kvList[i].(string)
+		v := kvList[i+1]
+
+		if om, ok := v.(zapcore.ObjectMarshaler); ok {
+			out = append(out, zap.Object(k, om))
+			continue
+		}
+
+		if m, ok := v.(base.Marshalable); ok {
+			v = m.Log()
+		}
+
+		out = append(out, k, v)
+	}
+
+	return out
+}
+
+func toZapLevel(level string) zapcore.Level {
+	switch level {
+	case base.LogLevel.All, base.LogLevel.Debug:
+		return zapcore.DebugLevel
+	case base.LogLevel.Info:
+		return zapcore.InfoLevel
+	case base.LogLevel.Error:
+		return zapcore.ErrorLevel
+	case base.LogLevel.None:
+		return noneLevel
+	default:
+		return zapcore.InfoLevel
+	}
+}